@@ -0,0 +1,106 @@
+// Copyright 2017 The kubecfg authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package metadata
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/ghodss/yaml"
+	"github.com/spf13/afero"
+
+	"github.com/ksonnet/kubecfg/metadata/registry"
+)
+
+const appSpecFilename = "app.yaml"
+
+// RegistryRefSpec is an application's reference to a registry it vendors
+// libraries from, as stored in app.yaml.
+type RegistryRefSpec struct {
+	Protocol string `json:"protocol"`
+	URI      string `json:"uri"`
+}
+
+// LibraryRefSpec is an application's reference to a single vendored
+// library, as stored in app.yaml.
+type LibraryRefSpec struct {
+	Registry string `json:"registry"`
+	Version  string `json:"version"`
+}
+
+// AppSpec is the parsed contents of an application's app.yaml.
+type AppSpec struct {
+	APIVersion string                     `json:"apiVersion"`
+	Registries map[string]RegistryRefSpec `json:"registries,omitempty"`
+	Libraries  map[string]LibraryRefSpec  `json:"libraries,omitempty"`
+}
+
+func (m *manager) readAppSpec() (*AppSpec, error) {
+	path := appendToAbsPath(m.rootPath, appSpecFilename)
+
+	data, err := afero.ReadFile(m.appFS, string(path))
+	if err != nil {
+		return nil, err
+	}
+
+	var spec AppSpec
+	if err := yaml.Unmarshal(data, &spec); err != nil {
+		return nil, fmt.Errorf("parse %s: %v", path, err)
+	}
+
+	return &spec, nil
+}
+
+func (m *manager) writeAppSpec(spec *AppSpec) error {
+	data, err := yaml.Marshal(spec)
+	if err != nil {
+		return err
+	}
+
+	path := appendToAbsPath(m.rootPath, appSpecFilename)
+	return m.writeFileAtomically(string(path), data, os.ModePerm)
+}
+
+// registrySpec looks up a named registry's connection details from app.yaml.
+func (m *manager) registrySpec(name string) (registry.Spec, error) {
+	app, err := m.readAppSpec()
+	if err != nil {
+		return registry.Spec{}, err
+	}
+
+	ref, ok := app.Registries[name]
+	if !ok {
+		return registry.Spec{}, fmt.Errorf("no such registry %q", name)
+	}
+
+	return registry.Spec{Protocol: registry.Protocol(ref.Protocol), URI: ref.URI}, nil
+}
+
+// recordLibrary rewrites app.yaml's `libraries` section to record that pkg
+// was vendored from registryName at version.
+func (m *manager) recordLibrary(pkg, registryName, version string) error {
+	app, err := m.readAppSpec()
+	if err != nil {
+		return err
+	}
+
+	if app.Libraries == nil {
+		app.Libraries = map[string]LibraryRefSpec{}
+	}
+	app.Libraries[pkg] = LibraryRefSpec{Registry: registryName, Version: version}
+
+	return m.writeAppSpec(app)
+}
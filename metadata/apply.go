@@ -0,0 +1,87 @@
+// Copyright 2017 The kubecfg authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package metadata
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/rest"
+
+	"github.com/ksonnet/kubecfg/pkg/apply"
+)
+
+// Apply installs objects into envName's target cluster, in the stable,
+// dependency-aware order pkg/apply computes (unless opts.Unordered is set,
+// in which case they're applied as given -- this is the `--ordered` CLI
+// flag's off switch).
+func (m *manager) Apply(envName string, objects []runtime.Object, opts apply.ApplyOptions) error {
+	env, err := m.findEnvironment(envName)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := restConfigForURI(env.URI)
+	if err != nil {
+		return err
+	}
+
+	client, err := apply.NewDynamicClient(cfg)
+	if err != nil {
+		return err
+	}
+
+	unstructuredObjs := make([]*unstructured.Unstructured, len(objects))
+	for i, obj := range objects {
+		u, err := toUnstructured(obj)
+		if err != nil {
+			return fmt.Errorf("apply: object %d: %v", i, err)
+		}
+		unstructuredObjs[i] = u
+	}
+
+	return apply.Apply(client, unstructuredObjs, opts)
+}
+
+func (m *manager) findEnvironment(name string) (*Environment, error) {
+	envs, err := m.GetEnvironments()
+	if err != nil {
+		return nil, err
+	}
+	for _, env := range envs {
+		if env.Name == name {
+			return &env, nil
+		}
+	}
+	return nil, fmt.Errorf("no such environment %q", name)
+}
+
+func toUnstructured(obj runtime.Object) (*unstructured.Unstructured, error) {
+	if u, ok := obj.(*unstructured.Unstructured); ok {
+		return u, nil
+	}
+
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	return &unstructured.Unstructured{Object: content}, nil
+}
+
+func restConfigForURI(uri string) (*rest.Config, error) {
+	return &rest.Config{Host: uri}, nil
+}
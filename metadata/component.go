@@ -0,0 +1,213 @@
+// Copyright 2017 The kubecfg authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package metadata
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/afero"
+
+	"github.com/ksonnet/kubecfg/metadata/params"
+)
+
+const componentsParamsFilename = "params.libsonnet"
+
+// ComponentParams is the set of parameter values (as jsonnet expression
+// text, e.g. `"foo"` or `3`) for a single component.
+type ComponentParams map[string]string
+
+// CreateComponent creates a new component file under the namespaced
+// component directory (creating the namespace directory if it doesn't
+// already exist) with `text` as its body, and registers `params` for it in
+// components/params.libsonnet. `template` selects the file extension the
+// component is written with (e.g. "jsonnet", "yaml", "json").
+func (m *manager) CreateComponent(name, text string, params ComponentParams, template string) error {
+	ns, base := filepath.Split(name)
+	nsPath := appendToAbsPath(m.componentsDir, ns)
+	if err := m.appFS.MkdirAll(string(nsPath), os.ModePerm); err != nil {
+		return err
+	}
+
+	componentPath := appendToAbsPath(nsPath, base+templateExtension(template))
+	if err := afero.WriteFile(m.appFS, string(componentPath), []byte(text), 0644); err != nil {
+		return err
+	}
+
+	return m.addComponentParams(name, params)
+}
+
+// DeleteComponent removes a component's source file and all references to it
+// from components/params.libsonnet and every environment's params.libsonnet.
+// Every rewritten file's bytes are computed in memory first; only once all
+// of them have been computed successfully are the writes performed and the
+// component's source file finally removed, so a failure partway through
+// leaves the application consistent.
+func (m *manager) DeleteComponent(name string) error {
+	componentPath, err := m.findComponentPath(name)
+	if err != nil {
+		return err
+	}
+
+	type rewrite struct {
+		path AbsPath
+		data []byte
+	}
+	var rewrites []rewrite
+
+	paramsPath := appendToAbsPath(m.componentsDir, componentsParamsFilename)
+	paramsText, err := afero.ReadFile(m.appFS, string(paramsPath))
+	if err != nil {
+		return err
+	}
+	rewritten, changed, err := params.DeleteComponent(string(paramsPath), string(paramsText), name)
+	if err != nil {
+		return err
+	}
+	if changed {
+		rewrites = append(rewrites, rewrite{paramsPath, []byte(rewritten)})
+	}
+
+	envs, err := m.GetEnvironments()
+	if err != nil {
+		return err
+	}
+	for _, env := range envs {
+		envParamsPath := appendToAbsPath(AbsPath(env.Path), componentsParamsFilename)
+		exists, err := afero.Exists(m.appFS, string(envParamsPath))
+		if err != nil {
+			return err
+		}
+		if !exists {
+			continue
+		}
+
+		envParamsText, err := afero.ReadFile(m.appFS, string(envParamsPath))
+		if err != nil {
+			return err
+		}
+		rewritten, changed, err := params.DeleteEnvironmentOverride(string(envParamsPath), string(envParamsText), name)
+		if err != nil {
+			return err
+		}
+		if changed {
+			rewrites = append(rewrites, rewrite{envParamsPath, []byte(rewritten)})
+		}
+	}
+
+	// Everything above only read from disk and computed bytes in memory.
+	// Only now do we start mutating the filesystem.
+	for _, r := range rewrites {
+		if err := afero.WriteFile(m.appFS, string(r.path), r.data, 0644); err != nil {
+			return err
+		}
+	}
+
+	return m.appFS.RemoveAll(string(componentPath))
+}
+
+// ComponentPaths enumerates the source file of every component under the
+// components directory.
+func (m *manager) ComponentPaths() (AbsPaths, error) {
+	paths := AbsPaths{}
+
+	err := afero.Walk(m.appFS, string(m.componentsDir), func(path string, f os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if f.IsDir() {
+			return nil
+		}
+		if filepath.Base(path) == componentsParamsFilename {
+			return nil
+		}
+		paths = append(paths, path)
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return paths, nil
+}
+
+func (m *manager) addComponentParams(name string, params0 ComponentParams) error {
+	paramsPath := appendToAbsPath(m.componentsDir, componentsParamsFilename)
+	existing, err := afero.ReadFile(m.appFS, string(paramsPath))
+	if err != nil {
+		return err
+	}
+
+	rewritten, err := params.AddComponent(string(paramsPath), string(existing), name, componentParamsJsonnet(params0))
+	if err != nil {
+		return err
+	}
+
+	return afero.WriteFile(m.appFS, string(paramsPath), []byte(rewritten), 0644)
+}
+
+// findComponentPath locates the source file for a component under the
+// components directory, trying each of the extensions a component can be
+// written in.
+func (m *manager) findComponentPath(name string) (AbsPath, error) {
+	for _, ext := range []string{".jsonnet", ".libsonnet", ".yaml", ".json"} {
+		candidate := appendToAbsPath(m.componentsDir, name+ext)
+		exists, err := afero.Exists(m.appFS, string(candidate))
+		if err != nil {
+			return "", err
+		}
+		if exists {
+			return candidate, nil
+		}
+	}
+	return "", errors.New("Component \"" + name + "\" does not exist.")
+}
+
+func templateExtension(template string) string {
+	switch template {
+	case "yaml":
+		return ".yaml"
+	case "json":
+		return ".json"
+	default:
+		return ".jsonnet"
+	}
+}
+
+func componentParamsJsonnet(p ComponentParams) string {
+	if len(p) == 0 {
+		return "{}"
+	}
+
+	keys := make([]string, 0, len(p))
+	for k := range p {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString("{\n")
+	for _, k := range keys {
+		fmt.Fprintf(&b, "      %s: %s,\n", k, p[k])
+	}
+	b.WriteString("    }")
+	return b.String()
+}
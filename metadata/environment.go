@@ -16,16 +16,21 @@
 package metadata
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/spf13/afero"
 
 	"github.com/ksonnet/ksonnet-lib/ksonnet-gen/ksonnet"
 	"github.com/ksonnet/ksonnet-lib/ksonnet-gen/kubespec"
+
+	"github.com/ksonnet/kubecfg/metadata/secrets"
 )
 
 const (
@@ -38,58 +43,203 @@ const (
 )
 
 type Environment struct {
-	Path string
-	Name string
-	URI  string
+	Path   string
+	Name   string
+	URI    string // primary (first) target's URI
+	Parent string
+}
+
+// EnvironmentTarget is a single cluster an environment applies to: its
+// server URI, the kubeconfig context/namespace to use, and, for
+// multi-cluster environments, an optional per-target params.libsonnet
+// override. Overrides is a path relative to the directory of the
+// environment (or nearest ancestor) that declares this target; when set,
+// ResolveEnvironment reads it into ResolvedEnvironment.TargetOverrides,
+// keyed by this target's URI.
+type EnvironmentTarget struct {
+	URI       string `json:"uri"`
+	Context   string `json:"context,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	Overrides string `json:"overrides,omitempty"`
 }
 
 type EnvironmentSpec struct {
-	URI string `json:"uri"`
+	Targets []EnvironmentTarget `json:"targets"`
+	// Parent is the name of the environment this one inherits its
+	// k8s.libsonnet/swagger.json and params from, storing only deltas.
+	// Empty for a root environment.
+	Parent string `json:"parent,omitempty"`
+	// SecretsBackend is the name of the encryption backend (see the
+	// metadata/secrets package) this environment's secrets are encrypted
+	// with, if any.
+	SecretsBackend string `json:"secretsBackend,omitempty"`
 }
 
-func (m *manager) CreateEnvironment(name, uri string, spec ClusterSpec, extensionsLibData, k8sLibData []byte) error {
+func (m *manager) CreateEnvironment(name string, targets []EnvironmentTarget, parent string, spec ClusterSpec, extensionsLibData, k8sLibData []byte, secretsConfig secrets.Config) error {
 	envPath := appendToAbsPath(m.environmentsDir, name)
 	err := m.appFS.MkdirAll(string(envPath), os.ModePerm)
 	if err != nil {
 		return err
 	}
 
-	// Get cluster specification data, possibly from the network.
-	specData, err := spec.data()
+	// Environments with a parent inherit their ksonnet-lib and don't store
+	// their own copy; only a root environment generates one.
+	if parent == "" {
+		specData, err := spec.data()
+		if err != nil {
+			return err
+		}
+
+		schemaPath := appendToAbsPath(envPath, schemaFilename)
+		if err := afero.WriteFile(m.appFS, string(schemaPath), specData, os.ModePerm); err != nil {
+			return err
+		}
+
+		k8sLibPath := appendToAbsPath(envPath, k8sLibFilename)
+		if err := afero.WriteFile(m.appFS, string(k8sLibPath), k8sLibData, 0644); err != nil {
+			return err
+		}
+
+		extensionsLibPath := appendToAbsPath(envPath, extensionsLibFilename)
+		if err := afero.WriteFile(m.appFS, string(extensionsLibPath), extensionsLibData, 0644); err != nil {
+			return err
+		}
+	}
+
+	// Persist the secrets backend config, if the environment declares one,
+	// alongside spec.json.
+	if secretsConfig.Backend != "" {
+		if err := m.writeSecretsConfig(name, secretsConfig); err != nil {
+			return err
+		}
+	}
+
+	// Generate the environment spec file.
+	envSpecData, err := generateSpecData(EnvironmentSpec{
+		Targets:        targets,
+		Parent:         parent,
+		SecretsBackend: string(secretsConfig.Backend),
+	})
 	if err != nil {
 		return err
 	}
 
-	// Generate the schema file.
-	schemaPath := appendToAbsPath(envPath, schemaFilename)
-	err = afero.WriteFile(m.appFS, string(schemaPath), specData, os.ModePerm)
+	envSpecPath := appendToAbsPath(envPath, specFilename)
+	return afero.WriteFile(m.appFS, string(envSpecPath), envSpecData, os.ModePerm)
+}
+
+// UpdateEnvironment regenerates ksonnet-lib for an existing environment
+// against newSpec, so that users can roll an environment forward to a new
+// Kubernetes version (e.g. `--version:v1.9.0` -> `v1.10.3`) without deleting
+// and recreating it. Only files whose contents actually changed are
+// rewritten.
+func (m *manager) UpdateEnvironment(name string, newSpec ClusterSpec) error {
+	envPath := appendToAbsPath(m.environmentsDir, name)
+
+	exists, err := afero.DirExists(m.appFS, string(envPath))
 	if err != nil {
 		return err
 	}
+	if !exists {
+		return errors.New("Environment \"" + name + "\" does not exist.")
+	}
 
-	k8sLibPath := appendToAbsPath(envPath, k8sLibFilename)
-	err = afero.WriteFile(m.appFS, string(k8sLibPath), k8sLibData, 0644)
+	specData, err := newSpec.data()
 	if err != nil {
 		return err
 	}
 
-	extensionsLibPath := appendToAbsPath(envPath, extensionsLibFilename)
-	err = afero.WriteFile(m.appFS, string(extensionsLibPath), extensionsLibData, 0644)
+	extensionsLibData, k8sLibData, err := m.GenerateKsonnetLibData(newSpec)
 	if err != nil {
 		return err
 	}
 
-	// Generate the environment spec file.
-	envSpecData, err := generateSpecData(uri)
+	rewrites := map[AbsPath][]byte{
+		appendToAbsPath(envPath, schemaFilename):        specData,
+		appendToAbsPath(envPath, k8sLibFilename):        k8sLibData,
+		appendToAbsPath(envPath, extensionsLibFilename): extensionsLibData,
+	}
+
+	for path, data := range rewrites {
+		changed, err := m.fileContentsDiffer(path, data)
+		if err != nil {
+			return err
+		}
+		if !changed {
+			continue
+		}
+		if err := afero.WriteFile(m.appFS, string(path), data, 0644); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// UpdateEnvironmentURI rewrites the primary (first) target's `uri` in an
+// existing environment's spec.json, replacing the file atomically so a
+// failed write can never leave spec.json truncated or half-written.
+func (m *manager) UpdateEnvironmentURI(name, newURI string) error {
+	envPath := appendToAbsPath(m.environmentsDir, name)
+	envSpecPath := appendToAbsPath(envPath, specFilename)
+
+	existing, err := afero.ReadFile(m.appFS, string(envSpecPath))
 	if err != nil {
 		return err
 	}
 
-	envSpecPath := appendToAbsPath(envPath, specFilename)
-	return afero.WriteFile(m.appFS, string(envSpecPath), envSpecData, os.ModePerm)
+	var envSpec EnvironmentSpec
+	if err := json.Unmarshal(existing, &envSpec); err != nil {
+		return err
+	}
+	if len(envSpec.Targets) == 0 {
+		envSpec.Targets = []EnvironmentTarget{{URI: newURI}}
+	} else {
+		envSpec.Targets[0].URI = newURI
+	}
+
+	envSpecData, err := generateSpecData(envSpec)
+	if err != nil {
+		return err
+	}
+
+	return m.writeFileAtomically(string(envSpecPath), envSpecData, os.ModePerm)
 }
 
-func (m *manager) DeleteEnvironment(name string) error {
+// fileContentsDiffer reports whether path does not yet exist, or exists with
+// contents different from data.
+func (m *manager) fileContentsDiffer(path AbsPath, data []byte) (bool, error) {
+	exists, err := afero.Exists(m.appFS, string(path))
+	if err != nil {
+		return false, err
+	}
+	if !exists {
+		return true, nil
+	}
+
+	existing, err := afero.ReadFile(m.appFS, string(path))
+	if err != nil {
+		return false, err
+	}
+
+	return !bytes.Equal(existing, data), nil
+}
+
+// writeFileAtomically writes data to a temp file alongside path and renames
+// it into place, so readers never observe a partially-written file.
+func (m *manager) writeFileAtomically(path string, data []byte, perm os.FileMode) error {
+	tmpPath := path + ".tmp"
+	if err := afero.WriteFile(m.appFS, tmpPath, data, perm); err != nil {
+		return err
+	}
+	return m.appFS.Rename(tmpPath, path)
+}
+
+// DeleteEnvironment removes an environment. If other environments declare it
+// as their Parent, DeleteEnvironment refuses unless cascade is true, in
+// which case those children (and their children, recursively) are deleted
+// first.
+func (m *manager) DeleteEnvironment(name string, cascade bool) error {
 	envPath := string(appendToAbsPath(m.environmentsDir, name))
 
 	envs, err := m.GetEnvironments()
@@ -97,14 +247,32 @@ func (m *manager) DeleteEnvironment(name string) error {
 		return err
 	}
 
-	var allEnvPaths map[string]*Environment
+	exists := false
+	var children []string
 	for _, env := range envs {
-		allEnvPaths[env.Path] = &env
+		if env.Name == name {
+			exists = true
+		}
+		if env.Parent == name {
+			children = append(children, env.Name)
+		}
 	}
 
 	// Check whether this environment exists
-	if allEnvPaths[envPath] == nil {
-		return errors.New("Environment \"" + string(envPath) + "\" does not exist.")
+	if !exists {
+		return errors.New("Environment \"" + name + "\" does not exist.")
+	}
+
+	if len(children) > 0 {
+		sort.Strings(children)
+		if !cascade {
+			return fmt.Errorf("environment %q has child environments (%s); pass --cascade to delete them too", name, strings.Join(children, ", "))
+		}
+		for _, child := range children {
+			if err := m.DeleteEnvironment(child, true); err != nil {
+				return err
+			}
+		}
 	}
 
 	// Remove the directory and all files within the environment path.
@@ -164,7 +332,28 @@ func (m *manager) GetEnvironments() ([]Environment, error) {
 					return err
 				}
 
-				envs = append(envs, Environment{Name: envName, Path: path, URI: envSpec.URI})
+				targets := envSpec.Targets
+				if len(targets) == 0 && envSpec.Parent != "" {
+					// A child environment storing only deltas doesn't repeat
+					// its own targets; resolve them from its nearest
+					// ancestor that declares some.
+					chain, err := m.ancestryChain(envName)
+					if err != nil {
+						return err
+					}
+					for _, node := range chain {
+						if len(node.Targets) > 0 {
+							targets = node.Targets
+							break
+						}
+					}
+				}
+
+				var uri string
+				if len(targets) > 0 {
+					uri = targets[0].URI
+				}
+				envs = append(envs, Environment{Name: envName, Path: path, URI: uri, Parent: envSpec.Parent})
 			}
 		}
 
@@ -201,7 +390,7 @@ func (m *manager) GenerateKsonnetLibData(spec ClusterSpec) ([]byte, []byte, erro
 	return ksonnet.Emit(&s, nil, nil)
 }
 
-func generateSpecData(uri string) ([]byte, error) {
+func generateSpecData(envSpec EnvironmentSpec) ([]byte, error) {
 	// Format the spec json and return; preface keys with 2 space idents.
-	return json.MarshalIndent(EnvironmentSpec{URI: uri}, "", "  ")
+	return json.MarshalIndent(envSpec, "", "  ")
 }
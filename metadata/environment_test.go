@@ -0,0 +1,81 @@
+// Copyright 2017 The kubecfg authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package metadata
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+func writeEnvSpec(t *testing.T, fs afero.Fs, name string, spec EnvironmentSpec) {
+	t.Helper()
+
+	envDir := "/app/environments/" + name
+	if err := fs.MkdirAll(envDir, 0755); err != nil {
+		t.Fatalf("MkdirAll(%s): %v", envDir, err)
+	}
+
+	data, err := generateSpecData(spec)
+	if err != nil {
+		t.Fatalf("generateSpecData: %v", err)
+	}
+	if err := afero.WriteFile(fs, envDir+"/"+specFilename, data, 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+}
+
+func TestGetEnvironmentsResolvesInheritedTargets(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	m := &manager{appFS: fs, environmentsDir: AbsPath("/app/environments")}
+
+	writeEnvSpec(t, fs, "prod", EnvironmentSpec{
+		Targets: []EnvironmentTarget{{URI: "https://prod.example.com"}},
+	})
+	writeEnvSpec(t, fs, "prod-canary", EnvironmentSpec{
+		Parent: "prod",
+	})
+
+	envs, err := m.GetEnvironments()
+	if err != nil {
+		t.Fatalf("GetEnvironments: %v", err)
+	}
+
+	byName := map[string]Environment{}
+	for _, env := range envs {
+		byName[env.Name] = env
+	}
+
+	child, ok := byName["prod-canary"]
+	if !ok {
+		t.Fatalf("GetEnvironments: missing prod-canary, got %+v", envs)
+	}
+	if child.URI != "https://prod.example.com" {
+		t.Errorf("prod-canary.URI = %q, want inherited parent URI %q", child.URI, "https://prod.example.com")
+	}
+}
+
+func TestGetEnvironmentsSurfacesInheritanceCycle(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	m := &manager{appFS: fs, environmentsDir: AbsPath("/app/environments")}
+
+	writeEnvSpec(t, fs, "a", EnvironmentSpec{Parent: "b"})
+	writeEnvSpec(t, fs, "b", EnvironmentSpec{Parent: "a"})
+
+	if _, err := m.GetEnvironments(); err == nil {
+		t.Fatal("GetEnvironments: got nil error for an inheritance cycle, want an error")
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright 2017 The kubecfg authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package metadata
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+// fileContentsDiffer backs UpdateEnvironment's "only rewrite what changed"
+// behavior; GenerateKsonnetLibData itself depends on the real ksonnet-lib
+// emitter, so fileContentsDiffer is exercised directly here rather than
+// through UpdateEnvironment end-to-end.
+func TestFileContentsDiffer(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	m := &manager{appFS: fs}
+
+	path := AbsPath("/app/environments/default/k8s.libsonnet")
+
+	differs, err := m.fileContentsDiffer(path, []byte("v1"))
+	if err != nil {
+		t.Fatalf("fileContentsDiffer (missing file): %v", err)
+	}
+	if !differs {
+		t.Error("fileContentsDiffer: missing file should always differ")
+	}
+
+	if err := afero.WriteFile(fs, string(path), []byte("v1"), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	differs, err = m.fileContentsDiffer(path, []byte("v1"))
+	if err != nil {
+		t.Fatalf("fileContentsDiffer (same contents): %v", err)
+	}
+	if differs {
+		t.Error("fileContentsDiffer: identical contents should not differ")
+	}
+
+	differs, err = m.fileContentsDiffer(path, []byte("v2"))
+	if err != nil {
+		t.Fatalf("fileContentsDiffer (different contents): %v", err)
+	}
+	if !differs {
+		t.Error("fileContentsDiffer: changed contents should differ")
+	}
+}
+
+func TestUpdateEnvironmentURIRoundTripsTargetsAndParent(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	m := &manager{appFS: fs, environmentsDir: AbsPath("/app/environments")}
+
+	writeEnvSpec(t, fs, "prod-canary", EnvironmentSpec{
+		Targets: []EnvironmentTarget{
+			{URI: "https://old.example.com", Context: "canary-ctx", Namespace: "canary"},
+		},
+		Parent: "prod",
+	})
+
+	if err := m.UpdateEnvironmentURI("prod-canary", "https://new.example.com"); err != nil {
+		t.Fatalf("UpdateEnvironmentURI: %v", err)
+	}
+
+	data, err := afero.ReadFile(fs, "/app/environments/prod-canary/"+specFilename)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+
+	var spec EnvironmentSpec
+	if err := json.Unmarshal(data, &spec); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	if spec.Parent != "prod" {
+		t.Errorf("spec.Parent = %q, want %q (untouched by URI swap)", spec.Parent, "prod")
+	}
+	if len(spec.Targets) != 1 {
+		t.Fatalf("spec.Targets = %+v, want exactly one target", spec.Targets)
+	}
+	if spec.Targets[0].URI != "https://new.example.com" {
+		t.Errorf("spec.Targets[0].URI = %q, want %q", spec.Targets[0].URI, "https://new.example.com")
+	}
+	if spec.Targets[0].Context != "canary-ctx" || spec.Targets[0].Namespace != "canary" {
+		t.Errorf("spec.Targets[0] = %+v, want Context/Namespace preserved", spec.Targets[0])
+	}
+}
+
+func TestUpdateEnvironmentURICreatesTargetWhenMissing(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	m := &manager{appFS: fs, environmentsDir: AbsPath("/app/environments")}
+
+	writeEnvSpec(t, fs, "inherited", EnvironmentSpec{Parent: "prod"})
+
+	if err := m.UpdateEnvironmentURI("inherited", "https://new.example.com"); err != nil {
+		t.Fatalf("UpdateEnvironmentURI: %v", err)
+	}
+
+	data, err := afero.ReadFile(fs, "/app/environments/inherited/"+specFilename)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+
+	var spec EnvironmentSpec
+	if err := json.Unmarshal(data, &spec); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	if len(spec.Targets) != 1 || spec.Targets[0].URI != "https://new.example.com" {
+		t.Errorf("spec.Targets = %+v, want a single new target with the given URI", spec.Targets)
+	}
+}
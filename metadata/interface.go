@@ -2,6 +2,11 @@ package metadata
 
 import (
 	"github.com/spf13/afero"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/ksonnet/kubecfg/metadata/prototype"
+	"github.com/ksonnet/kubecfg/metadata/secrets"
+	"github.com/ksonnet/kubecfg/pkg/apply"
 )
 
 var appFS afero.Fs
@@ -22,15 +27,46 @@ type Manager interface {
 	ComponentPaths() (AbsPaths, error)
 	LibPaths(envName string) (libPath, envLibPath AbsPath)
 	GenerateKsonnetLibData(spec ClusterSpec) ([]byte, []byte, error)
-	CreateEnvironment(name, uri string, spec ClusterSpec, extensionsLibData, k8sLibData []byte) error
-	//
-	// TODO: Fill in methods as we need them.
-	//
-	// GetPrototype(id string) Protoype
-	// SearchPrototypes(query string) []Protoype
-	// VendorLibrary(uri, version string) error
-	// DeleteEnv(name string) error
-	//
+	// CreateEnvironment creates a new environment targeting one or more
+	// clusters. If parent is non-empty, the environment inherits its
+	// ksonnet-lib and params from that ancestor instead of generating its
+	// own, storing only the deltas given here.
+	CreateEnvironment(name string, targets []EnvironmentTarget, parent string, spec ClusterSpec, extensionsLibData, k8sLibData []byte, secretsConfig secrets.Config) error
+	// UpdateEnvironment regenerates ksonnet-lib for an existing environment
+	// against newSpec (e.g. to roll the environment forward to a new
+	// Kubernetes version), rewriting only the files whose contents changed.
+	UpdateEnvironment(name string, newSpec ClusterSpec) error
+	// UpdateEnvironmentURI atomically rewrites the primary target's `uri` in
+	// an existing environment's spec.json.
+	UpdateEnvironmentURI(name, newURI string) error
+	// DeleteEnvironment removes an environment, refusing if other
+	// environments declare it as their parent unless cascade is true.
+	DeleteEnvironment(name string, cascade bool) error
+	// ResolveEnvironment returns the flattened view of an environment after
+	// walking its inheritance chain.
+	ResolveEnvironment(name string) (*ResolvedEnvironment, error)
+	CreateComponent(name, text string, params ComponentParams, template string) error
+	DeleteComponent(name string) error
+	// GetPrototype returns the vendored prototype named id.
+	GetPrototype(id string) (*prototype.Prototype, error)
+	// SearchPrototypes fuzzy-searches every vendored library's prototypes.
+	SearchPrototypes(query string) ([]*prototype.Prototype, error)
+	// VendorLibrary fetches pkgName@version from the named registry,
+	// checksum-verifies it, and vendors it under vendor/<registry>/<pkg>/.
+	VendorLibrary(registryName, pkgName, version string) error
+	// EncryptSecret encrypts plaintext with envName's declared secrets
+	// backend.
+	EncryptSecret(envName string, plaintext []byte) ([]byte, error)
+	// DecryptSecret decrypts ciphertext with envName's declared secrets
+	// backend.
+	DecryptSecret(envName string, ciphertext []byte) ([]byte, error)
+	// ReadSecretFile reads the raw (encrypted) contents of a file path
+	// relative to envName's environment directory, e.g.
+	// "secrets/foo.enc.json".
+	ReadSecretFile(envName, relPath string) ([]byte, error)
+	// Apply installs objects into envName's target cluster in a stable,
+	// dependency-aware order (see pkg/apply).
+	Apply(envName string, objects []runtime.Object, opts apply.ApplyOptions) error
 }
 
 // Find will recursively search the current directory and its parents for a
@@ -0,0 +1,201 @@
+// Copyright 2017 The kubecfg authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+// Package params parses, mutates, and emits the components/params.libsonnet
+// and per-environment params.libsonnet files as jsonnet ASTs, rather than via
+// regex or full-document reserialization, so edits preserve whatever
+// formatting a user already has.
+package params
+
+import (
+	"fmt"
+
+	"github.com/google/go-jsonnet/ast"
+	"github.com/google/go-jsonnet/parser"
+)
+
+// componentsKey is the field name of the top-level object in
+// components/params.libsonnet that holds each component's parameters.
+const componentsKey = "components"
+
+// DeleteComponent removes `component`'s entry from the `components` object
+// of a components/params.libsonnet document, returning the rewritten text.
+// ok is false if the component had no entry to begin with, letting callers
+// distinguish "nothing to do" from "made an edit".
+func DeleteComponent(filename, text, component string) (rewritten string, ok bool, err error) {
+	obj, err := parseTopLevelObject(filename, text)
+	if err != nil {
+		return "", false, err
+	}
+
+	components, err := findField(obj, componentsKey)
+	if err != nil {
+		return "", false, err
+	}
+	if components == nil {
+		return text, false, nil
+	}
+
+	inner, isObj := components.Expr2.(*ast.Object)
+	if !isObj {
+		return "", false, fmt.Errorf("%s: %q is not an object", filename, componentsKey)
+	}
+
+	field, err := findField(inner, component)
+	if err != nil {
+		return "", false, err
+	}
+	if field == nil {
+		return text, false, nil
+	}
+
+	return spliceOutField(text, field), true, nil
+}
+
+// DeleteEnvironmentOverride removes `component`'s override from a per-
+// environment params.libsonnet document (a top-level object keyed directly
+// by component name), returning the rewritten text.
+func DeleteEnvironmentOverride(filename, text, component string) (rewritten string, ok bool, err error) {
+	obj, err := parseTopLevelObject(filename, text)
+	if err != nil {
+		return "", false, err
+	}
+
+	field, err := findField(obj, component)
+	if err != nil {
+		return "", false, err
+	}
+	if field == nil {
+		return text, false, nil
+	}
+
+	return spliceOutField(text, field), true, nil
+}
+
+// AddComponent inserts a `"name": paramsText,` entry into the `components`
+// object of a components/params.libsonnet document. If an entry for name
+// already exists, the text is returned unchanged.
+func AddComponent(filename, text, name, paramsText string) (string, error) {
+	obj, err := parseTopLevelObject(filename, text)
+	if err != nil {
+		return "", err
+	}
+
+	components, err := findField(obj, componentsKey)
+	if err != nil {
+		return "", err
+	}
+	if components == nil {
+		return "", fmt.Errorf("%s: missing %q object", filename, componentsKey)
+	}
+
+	inner, isObj := components.Expr2.(*ast.Object)
+	if !isObj {
+		return "", fmt.Errorf("%s: %q is not an object", filename, componentsKey)
+	}
+
+	existing, err := findField(inner, name)
+	if err != nil {
+		return "", err
+	}
+	if existing != nil {
+		return text, nil
+	}
+
+	insertAt := byteOffset(text, inner.NodeBase.Loc().End) - 1
+	for insertAt > 0 && isSpace(text[insertAt-1]) {
+		insertAt--
+	}
+
+	entry := fmt.Sprintf("\n    %q: %s,", name, paramsText)
+	return text[:insertAt] + entry + text[insertAt:], nil
+}
+
+// parseTopLevelObject parses text and asserts that its root is a jsonnet
+// object literal, which both params.libsonnet flavors always are.
+func parseTopLevelObject(filename, text string) (*ast.Object, error) {
+	root, err := parser.SnippetToAST(filename, text)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %v", filename, err)
+	}
+
+	obj, ok := root.(*ast.Object)
+	if !ok {
+		return nil, fmt.Errorf("%s: expected a top-level object", filename)
+	}
+
+	return obj, nil
+}
+
+// findField returns the field named `name` in obj, or nil if there is none.
+// Component names routinely contain characters (`-`, `/`) that aren't valid
+// in a bare jsonnet identifier, so fields keyed either way -- `id: ...` or
+// `"id": ...` -- must both be matched.
+func findField(obj *ast.Object, name string) (*ast.ObjectField, error) {
+	for i := range obj.Fields {
+		field := &obj.Fields[i]
+		if field.Id != nil && string(*field.Id) == name {
+			return field, nil
+		}
+		if str, ok := field.Expr1.(*ast.LiteralString); ok && field.Kind == ast.ObjectFieldStr && str.Value == name {
+			return field, nil
+		}
+	}
+	return nil, nil
+}
+
+// spliceOutField removes the byte range covered by field (plus its leading
+// indentation, any trailing commas, spaces and tabs, and the newline right
+// after them) from text, leaving the formatting of the rest of the document
+// untouched.
+func spliceOutField(text string, field *ast.ObjectField) string {
+	loc := field.NodeBase.Loc()
+	start := byteOffset(text, loc.Begin)
+	end := byteOffset(text, loc.End)
+
+	for end < len(text) && (text[end] == ',' || isSpace(text[end])) && text[end] != '\n' {
+		end++
+	}
+	if end < len(text) && text[end] == '\n' {
+		end++
+	}
+	for start > 0 && isSpace(text[start-1]) {
+		start--
+	}
+
+	return text[:start] + text[end:]
+}
+
+func isSpace(b byte) bool {
+	return b == ' ' || b == '\t'
+}
+
+// byteOffset converts a 1-indexed (line, column) jsonnet AST location into a
+// byte offset into text.
+func byteOffset(text string, loc ast.Location) int {
+	line, col := 1, 1
+	for i, r := range text {
+		if line == loc.Line && col == loc.Column {
+			return i
+		}
+		if r == '\n' {
+			line++
+			col = 1
+		} else {
+			col++
+		}
+	}
+	return len(text)
+}
@@ -0,0 +1,109 @@
+// Copyright 2017 The kubecfg authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package params
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAddComponentQuotesHyphenatedNames(t *testing.T) {
+	text := `{
+  components: {
+  },
+}
+`
+
+	for _, name := range []string{"nginx-deployment", "redis-cache", "myns/foo"} {
+		rewritten, err := AddComponent("params.libsonnet", text, name, "{}")
+		if err != nil {
+			t.Fatalf("AddComponent(%q): %v", name, err)
+		}
+
+		want := `"` + name + `":`
+		if !strings.Contains(rewritten, want) {
+			t.Errorf("AddComponent(%q): rewritten text missing quoted key %s, got:\n%s", name, want, rewritten)
+		}
+
+		if _, err := parseTopLevelObject("params.libsonnet", rewritten); err != nil {
+			t.Errorf("AddComponent(%q): rewritten text does not parse: %v", name, err)
+		}
+	}
+}
+
+func TestAddComponentIdempotent(t *testing.T) {
+	text := `{
+  components: {
+    "nginx-deployment": { foo: "bar" },
+  },
+}
+`
+
+	rewritten, err := AddComponent("params.libsonnet", text, "nginx-deployment", "{}")
+	if err != nil {
+		t.Fatalf("AddComponent: %v", err)
+	}
+	if rewritten != text {
+		t.Errorf("AddComponent should leave text unchanged when the component already exists, got:\n%s", rewritten)
+	}
+}
+
+func TestFindFieldMatchesQuotedKeys(t *testing.T) {
+	text := `{
+  "nginx-deployment": { foo: "bar" },
+  plainId: { baz: "qux" },
+}
+`
+
+	obj, err := parseTopLevelObject("params.libsonnet", text)
+	if err != nil {
+		t.Fatalf("parseTopLevelObject: %v", err)
+	}
+
+	for _, name := range []string{"nginx-deployment", "plainId"} {
+		field, err := findField(obj, name)
+		if err != nil {
+			t.Fatalf("findField(%q): %v", name, err)
+		}
+		if field == nil {
+			t.Errorf("findField(%q) = nil, want a match", name)
+		}
+	}
+}
+
+func TestDeleteComponentHyphenatedName(t *testing.T) {
+	text := `{
+  components: {
+    "nginx-deployment": { foo: "bar" },
+    other: { baz: "qux" },
+  },
+}
+`
+
+	rewritten, ok, err := DeleteComponent("params.libsonnet", text, "nginx-deployment")
+	if err != nil {
+		t.Fatalf("DeleteComponent: %v", err)
+	}
+	if !ok {
+		t.Fatalf("DeleteComponent: ok = false, want true")
+	}
+	if strings.Contains(rewritten, "nginx-deployment") {
+		t.Errorf("DeleteComponent: rewritten text still contains deleted component:\n%s", rewritten)
+	}
+	if !strings.Contains(rewritten, "other") {
+		t.Errorf("DeleteComponent: rewritten text lost unrelated component:\n%s", rewritten)
+	}
+}
@@ -0,0 +1,144 @@
+// Copyright 2017 The kubecfg authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+// Package prototype parses vendored prototype snippets -- jsonnet templates
+// with a YAML front-matter header declaring their parameters -- and provides
+// a small fuzzy search index over a collection of them.
+package prototype
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+)
+
+const frontMatterDelim = "---\n"
+
+// Param is a single named, defaultable parameter a prototype accepts.
+type Param struct {
+	Name        string `json:"name"`
+	Default     string `json:"default"`
+	Description string `json:"description"`
+}
+
+// Prototype is a reusable component snippet: a jsonnet template plus the
+// metadata needed to search for it and materialize it with concrete
+// parameter values.
+type Prototype struct {
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Tags        []string `json:"tags"`
+	Params      []Param `json:"params"`
+	Template    string  `json:"-"`
+}
+
+// Parse splits a prototype file's text into its YAML front matter (bounded
+// by `---` lines) and its jsonnet template body.
+func Parse(text string) (*Prototype, error) {
+	if !strings.HasPrefix(text, frontMatterDelim) {
+		return nil, fmt.Errorf("prototype: missing front matter")
+	}
+
+	rest := text[len(frontMatterDelim):]
+	end := strings.Index(rest, frontMatterDelim)
+	if end < 0 {
+		return nil, fmt.Errorf("prototype: unterminated front matter")
+	}
+
+	var p Prototype
+	if err := yaml.Unmarshal([]byte(rest[:end]), &p); err != nil {
+		return nil, fmt.Errorf("prototype: parse front matter: %v", err)
+	}
+	p.Template = rest[end+len(frontMatterDelim):]
+
+	return &p, nil
+}
+
+// Materialize substitutes values (falling back to each param's declared
+// default) into the prototype's template, producing the text of a new
+// component.
+func (p *Prototype) Materialize(values map[string]string) string {
+	out := p.Template
+	for _, param := range p.Params {
+		v, ok := values[param.Name]
+		if !ok {
+			v = param.Default
+		}
+		out = strings.Replace(out, "{{"+param.Name+"}}", v, -1)
+	}
+	return out
+}
+
+// Index is a small, in-memory fuzzy-searchable collection of prototypes.
+type Index []*Prototype
+
+// Search ranks prototypes against query using a BM25-like score computed
+// over each prototype's name, description, and tags, where both whole-token
+// and substring matches count as hits.
+func (idx Index) Search(query string) []*Prototype {
+	terms := strings.Fields(strings.ToLower(query))
+
+	type scored struct {
+		p     *Prototype
+		score float64
+	}
+	var ranked []scored
+	for _, p := range idx {
+		if s := score(p, terms); s > 0 {
+			ranked = append(ranked, scored{p, s})
+		}
+	}
+
+	sort.Slice(ranked, func(i, j int) bool { return ranked[i].score > ranked[j].score })
+
+	results := make([]*Prototype, len(ranked))
+	for i, r := range ranked {
+		results[i] = r.p
+	}
+	return results
+}
+
+// BM25-ish scoring constants; tuned for short documents (a handful of
+// words), not a full-text corpus.
+const (
+	k1         = 1.2
+	b          = 0.75
+	avgDocTerms = 8.0
+)
+
+func score(p *Prototype, terms []string) float64 {
+	doc := strings.ToLower(strings.Join(append([]string{p.Name, p.Description}, p.Tags...), " "))
+	docTerms := strings.Fields(doc)
+
+	var total float64
+	for _, t := range terms {
+		var tf float64
+		for _, d := range docTerms {
+			if d == t {
+				tf++
+			} else if strings.Contains(d, t) {
+				tf += 0.5
+			}
+		}
+		if tf == 0 {
+			continue
+		}
+		norm := 1 - b + b*float64(len(docTerms))/avgDocTerms
+		total += tf * (k1 + 1) / (tf + k1*norm)
+	}
+	return total
+}
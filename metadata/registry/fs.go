@@ -0,0 +1,86 @@
+// Copyright 2017 The kubecfg authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package registry
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/afero"
+)
+
+// filesystemRegistry exposes packages rooted at a local directory, one
+// subdirectory per package. It has no natural notion of a SHA, so it treats
+// a content hash of the package tree as its version.
+type filesystemRegistry struct {
+	name string
+	root string
+	fs   afero.Fs
+}
+
+func newFilesystemRegistry(name, root string) *filesystemRegistry {
+	return &filesystemRegistry{name: name, root: root, fs: afero.NewOsFs()}
+}
+
+func (r *filesystemRegistry) Name() string { return r.name }
+
+func (r *filesystemRegistry) ResolveVersion(pkg, version string) (string, error) {
+	tree, err := r.Fetch(pkg, "")
+	if err != nil {
+		return "", err
+	}
+	return Hash(tree), nil
+}
+
+func (r *filesystemRegistry) Fetch(pkg, sha string) (map[string][]byte, error) {
+	pkgRoot := filepath.Join(r.root, pkg)
+
+	tree := map[string][]byte{}
+	err := afero.Walk(r.fs, pkgRoot, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(pkgRoot, path)
+		if err != nil {
+			return err
+		}
+
+		data, err := afero.ReadFile(r.fs, path)
+		if err != nil {
+			return err
+		}
+		tree[rel] = data
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("registry %s: fetch %s: %v", r.name, pkg, err)
+	}
+
+	return tree, nil
+}
+
+func (r *filesystemRegistry) Checksum(pkg, sha string) (string, error) {
+	tree, err := r.Fetch(pkg, sha)
+	if err != nil {
+		return "", err
+	}
+	return Hash(tree), nil
+}
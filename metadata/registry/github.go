@@ -0,0 +1,98 @@
+// Copyright 2017 The kubecfg authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package registry
+
+import (
+	"fmt"
+	"strings"
+)
+
+// githubRegistry resolves packages hosted as subdirectories of a single
+// GitHub repository, addressed as `<owner>/<repo>`.
+type githubRegistry struct {
+	name string
+	repo string
+}
+
+func newGitHubRegistry(name, repo string) *githubRegistry {
+	return &githubRegistry{name: name, repo: strings.Trim(repo, "/")}
+}
+
+func (r *githubRegistry) Name() string { return r.name }
+
+func (r *githubRegistry) ResolveVersion(pkg, version string) (string, error) {
+	if version == "" {
+		version = "master"
+	}
+
+	var commit struct {
+		SHA string `json:"sha"`
+	}
+	url := fmt.Sprintf("https://api.github.com/repos/%s/commits/%s", r.repo, version)
+	if err := getJSON(url, &commit); err != nil {
+		return "", fmt.Errorf("registry %s: resolve %s@%s: %v", r.name, pkg, version, err)
+	}
+
+	return commit.SHA, nil
+}
+
+func (r *githubRegistry) Fetch(pkg, sha string) (map[string][]byte, error) {
+	var tree struct {
+		Tree []struct {
+			Path string `json:"path"`
+			Type string `json:"type"`
+		} `json:"tree"`
+	}
+	url := fmt.Sprintf("https://api.github.com/repos/%s/git/trees/%s?recursive=1", r.repo, sha)
+	if err := getJSON(url, &tree); err != nil {
+		return nil, fmt.Errorf("registry %s: fetch tree for %s@%s: %v", r.name, pkg, sha, err)
+	}
+
+	prefix := pkg + "/"
+	files := map[string][]byte{}
+	for _, entry := range tree.Tree {
+		if entry.Type != "blob" || !strings.HasPrefix(entry.Path, prefix) {
+			continue
+		}
+
+		rawURL := fmt.Sprintf("https://raw.githubusercontent.com/%s/%s/%s", r.repo, sha, entry.Path)
+		data, err := getBytes(rawURL)
+		if err != nil {
+			return nil, fmt.Errorf("registry %s: fetch %s: %v", r.name, entry.Path, err)
+		}
+
+		files[strings.TrimPrefix(entry.Path, prefix)] = data
+	}
+
+	return files, nil
+}
+
+// Checksum reads a CHECKSUM file published alongside pkg in the repo. Unlike
+// the Helm backend, GitHub has no native manifest digest to reuse, so the
+// published CHECKSUM is expected to already be in registry.Hash's own
+// format: a sha256 over each fetched file's path (relative to pkg's root,
+// as Fetch strips it) concatenated with its contents, sorted by path. A
+// registry author can produce one by running Hash over the same tree Fetch
+// would return and committing the result as CHECKSUM.
+func (r *githubRegistry) Checksum(pkg, sha string) (string, error) {
+	url := fmt.Sprintf("https://raw.githubusercontent.com/%s/%s/%s/CHECKSUM", r.repo, sha, pkg)
+	data, err := getBytes(url)
+	if err != nil {
+		return "", fmt.Errorf("registry %s: fetch checksum for %s@%s: %v", r.name, pkg, sha, err)
+	}
+
+	return strings.TrimSpace(string(data)), nil
+}
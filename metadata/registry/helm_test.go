@@ -0,0 +1,63 @@
+// Copyright 2017 The kubecfg authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package registry
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHelmRegistryChecksumMatchesFetchHash(t *testing.T) {
+	tarball := []byte("fake chart tarball bytes")
+	digest := sha256.Sum256(tarball)
+	sha := hex.EncodeToString(digest[:])
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/chart.tgz", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(tarball)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	mux.HandleFunc("/index.yaml", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "entries:\n  mychart:\n    - version: \"1.0.0\"\n      digest: %s\n      urls:\n        - %s/chart.tgz\n", sha, srv.URL)
+	})
+
+	reg := newHelmRegistry("test", srv.URL)
+
+	resolvedSha, err := reg.ResolveVersion("mychart", "1.0.0")
+	if err != nil {
+		t.Fatalf("ResolveVersion: %v", err)
+	}
+
+	tree, err := reg.Fetch("mychart", resolvedSha)
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+
+	expected, err := reg.Checksum("mychart", resolvedSha)
+	if err != nil {
+		t.Fatalf("Checksum: %v", err)
+	}
+
+	if actual := Hash(tree); actual != expected {
+		t.Errorf("Hash(tree) = %q, Checksum() = %q; want equal, since VendorLibrary compares them directly", actual, expected)
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright 2017 The kubecfg authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+// Package registry understands remote registries of vendorable ksonnet
+// libraries -- GitHub repositories, local filesystem trees, and Helm-style
+// HTTP chart repositories -- each described by a registry.yaml manifest.
+package registry
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+)
+
+// Protocol identifies how a registry's packages are addressed and fetched.
+type Protocol string
+
+const (
+	ProtocolGitHub     Protocol = "github"
+	ProtocolFilesystem Protocol = "fs"
+	ProtocolHelm       Protocol = "helm"
+)
+
+// Spec is the parsed contents of a registry.yaml manifest.
+type Spec struct {
+	APIVersion string   `json:"apiVersion"`
+	Protocol   Protocol `json:"protocol"`
+	URI        string   `json:"uri"`
+}
+
+// Registry is the interface every supported registry protocol implements.
+type Registry interface {
+	Name() string
+	// ResolveVersion pins a human-readable version (tag, branch, "latest")
+	// of pkg to an immutable SHA.
+	ResolveVersion(pkg, version string) (sha string, err error)
+	// Fetch retrieves the full file tree of pkg at sha, keyed by path
+	// relative to the package root.
+	Fetch(pkg, sha string) (map[string][]byte, error)
+	// Checksum returns the registry-published checksum for pkg at sha, so
+	// callers can verify what Fetch returned.
+	Checksum(pkg, sha string) (string, error)
+}
+
+// New constructs the Registry implementation for spec's protocol.
+func New(name string, spec Spec) (Registry, error) {
+	switch spec.Protocol {
+	case ProtocolGitHub:
+		return newGitHubRegistry(name, spec.URI), nil
+	case ProtocolFilesystem:
+		return newFilesystemRegistry(name, spec.URI), nil
+	case ProtocolHelm:
+		return newHelmRegistry(name, spec.URI), nil
+	default:
+		return nil, fmt.Errorf("registry %q: unsupported protocol %q", name, spec.Protocol)
+	}
+}
+
+// Hash computes a stable content hash over a file tree as returned by
+// Fetch, suitable for registries (like the filesystem one) that have no
+// natural notion of a SHA of their own.
+func Hash(tree map[string][]byte) string {
+	paths := make([]string, 0, len(tree))
+	for p := range tree {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	h := sha256.New()
+	for _, p := range paths {
+		h.Write([]byte(p))
+		h.Write(tree[p])
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func getBytes(url string) ([]byte, error) {
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+
+	return ioutil.ReadAll(resp.Body)
+}
+
+func getJSON(url string, v interface{}) error {
+	data, err := getBytes(url)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
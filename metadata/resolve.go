@@ -0,0 +1,149 @@
+// Copyright 2017 The kubecfg authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package metadata
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/afero"
+)
+
+// ResolvedEnvironment is the flattened view of an environment after walking
+// its inheritance chain: its own targets (or, if it declares none, those of
+// the nearest ancestor that does), plus whichever ancestor last defined the
+// ksonnet-lib files it didn't override itself.
+type ResolvedEnvironment struct {
+	Name              string
+	Targets           []EnvironmentTarget
+	SchemaData        []byte
+	K8sLibData        []byte
+	ExtensionsLibData []byte
+	SecretsBackend    string
+	// TargetOverrides holds the contents of each target's Overrides
+	// params.libsonnet file, keyed by the target's URI, for targets that
+	// declare one.
+	TargetOverrides map[string][]byte
+}
+
+// envNode is a single link in an environment's inheritance chain.
+type envNode struct {
+	Name string
+	EnvironmentSpec
+}
+
+// ResolveEnvironment walks name's inheritance chain (following Parent back
+// to a root environment) and returns the flattened view: its targets, and
+// the ksonnet-lib files closest to it in the chain that actually define
+// them.
+func (m *manager) ResolveEnvironment(name string) (*ResolvedEnvironment, error) {
+	chain, err := m.ancestryChain(name)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved := &ResolvedEnvironment{Name: name}
+
+	// Walk from the root ancestor down to name, so a descendant's own files
+	// (if any) win over an ancestor's.
+	for i := len(chain) - 1; i >= 0; i-- {
+		node := chain[i]
+		envPath := appendToAbsPath(m.environmentsDir, node.Name)
+
+		if data, err := afero.ReadFile(m.appFS, string(appendToAbsPath(envPath, schemaFilename))); err == nil {
+			resolved.SchemaData = data
+		}
+		if data, err := afero.ReadFile(m.appFS, string(appendToAbsPath(envPath, k8sLibFilename))); err == nil {
+			resolved.K8sLibData = data
+		}
+		if data, err := afero.ReadFile(m.appFS, string(appendToAbsPath(envPath, extensionsLibFilename))); err == nil {
+			resolved.ExtensionsLibData = data
+		}
+		if node.SecretsBackend != "" {
+			resolved.SecretsBackend = node.SecretsBackend
+		}
+	}
+
+	// A child environment storing only deltas doesn't repeat its own
+	// targets; walk back up the chain to the nearest ancestor that
+	// declares some, the same as GetEnvironments does.
+	var targetSource string
+	for _, node := range chain {
+		if len(node.Targets) > 0 {
+			resolved.Targets = node.Targets
+			targetSource = node.Name
+			break
+		}
+	}
+
+	for _, target := range resolved.Targets {
+		if target.Overrides == "" {
+			continue
+		}
+		envPath := appendToAbsPath(m.environmentsDir, targetSource)
+		data, err := afero.ReadFile(m.appFS, string(appendToAbsPath(envPath, target.Overrides)))
+		if err != nil {
+			return nil, fmt.Errorf("environment %q: target %q: read overrides %q: %v", name, target.URI, target.Overrides, err)
+		}
+		if resolved.TargetOverrides == nil {
+			resolved.TargetOverrides = map[string][]byte{}
+		}
+		resolved.TargetOverrides[target.URI] = data
+	}
+
+	return resolved, nil
+}
+
+// ancestryChain returns name's inheritance chain, starting with name itself
+// and ending with its root (non-inheriting) ancestor.
+func (m *manager) ancestryChain(name string) ([]envNode, error) {
+	var chain []envNode
+	seen := map[string]bool{}
+
+	cur := name
+	for cur != "" {
+		if seen[cur] {
+			return nil, fmt.Errorf("environment %q: inheritance cycle through %q", name, cur)
+		}
+		seen[cur] = true
+
+		spec, err := m.readEnvironmentSpec(cur)
+		if err != nil {
+			return nil, fmt.Errorf("environment %q: %v", name, err)
+		}
+
+		chain = append(chain, envNode{Name: cur, EnvironmentSpec: spec})
+		cur = spec.Parent
+	}
+
+	return chain, nil
+}
+
+func (m *manager) readEnvironmentSpec(name string) (EnvironmentSpec, error) {
+	path := appendToAbsPath(appendToAbsPath(m.environmentsDir, name), specFilename)
+
+	data, err := afero.ReadFile(m.appFS, string(path))
+	if err != nil {
+		return EnvironmentSpec{}, err
+	}
+
+	var spec EnvironmentSpec
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return EnvironmentSpec{}, err
+	}
+
+	return spec, nil
+}
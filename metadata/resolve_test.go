@@ -0,0 +1,93 @@
+// Copyright 2017 The kubecfg authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package metadata
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+func TestResolveEnvironmentResolvesInheritedTargets(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	m := &manager{appFS: fs, environmentsDir: AbsPath("/app/environments")}
+
+	writeEnvSpec(t, fs, "prod", EnvironmentSpec{
+		Targets: []EnvironmentTarget{{URI: "https://prod.example.com"}},
+	})
+	writeEnvSpec(t, fs, "prod-canary", EnvironmentSpec{
+		Parent: "prod",
+	})
+
+	resolved, err := m.ResolveEnvironment("prod-canary")
+	if err != nil {
+		t.Fatalf("ResolveEnvironment: %v", err)
+	}
+
+	if len(resolved.Targets) != 1 || resolved.Targets[0].URI != "https://prod.example.com" {
+		t.Errorf("ResolveEnvironment(\"prod-canary\").Targets = %+v, want inherited parent target", resolved.Targets)
+	}
+}
+
+func TestResolveEnvironmentOwnTargetsWinOverInherited(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	m := &manager{appFS: fs, environmentsDir: AbsPath("/app/environments")}
+
+	writeEnvSpec(t, fs, "prod", EnvironmentSpec{
+		Targets: []EnvironmentTarget{{URI: "https://prod.example.com"}},
+	})
+	writeEnvSpec(t, fs, "prod-canary", EnvironmentSpec{
+		Parent:  "prod",
+		Targets: []EnvironmentTarget{{URI: "https://prod-canary.example.com"}},
+	})
+
+	resolved, err := m.ResolveEnvironment("prod-canary")
+	if err != nil {
+		t.Fatalf("ResolveEnvironment: %v", err)
+	}
+
+	if len(resolved.Targets) != 1 || resolved.Targets[0].URI != "https://prod-canary.example.com" {
+		t.Errorf("ResolveEnvironment(\"prod-canary\").Targets = %+v, want own target", resolved.Targets)
+	}
+}
+
+func TestResolveEnvironmentReadsTargetOverrides(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	m := &manager{appFS: fs, environmentsDir: AbsPath("/app/environments")}
+
+	writeEnvSpec(t, fs, "prod", EnvironmentSpec{
+		Targets: []EnvironmentTarget{
+			{URI: "https://prod.example.com", Overrides: "params-prod.libsonnet"},
+		},
+	})
+	overridesData := []byte("{ replicas: 5 }")
+	if err := afero.WriteFile(fs, "/app/environments/prod/params-prod.libsonnet", overridesData, 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	resolved, err := m.ResolveEnvironment("prod")
+	if err != nil {
+		t.Fatalf("ResolveEnvironment: %v", err)
+	}
+
+	got, ok := resolved.TargetOverrides["https://prod.example.com"]
+	if !ok {
+		t.Fatalf("ResolveEnvironment: TargetOverrides missing entry for target, got %+v", resolved.TargetOverrides)
+	}
+	if string(got) != string(overridesData) {
+		t.Errorf("TargetOverrides = %q, want %q", got, overridesData)
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright 2017 The kubecfg authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package metadata
+
+import (
+	"fmt"
+
+	"github.com/ghodss/yaml"
+	"github.com/spf13/afero"
+
+	"github.com/ksonnet/kubecfg/metadata/secrets"
+)
+
+const secretsFilename = "secrets.yaml"
+
+// EncryptSecret encrypts plaintext with envName's declared secrets backend.
+func (m *manager) EncryptSecret(envName string, plaintext []byte) ([]byte, error) {
+	enc, err := m.secretsEncryptor(envName)
+	if err != nil {
+		return nil, err
+	}
+	return enc.Encrypt(plaintext)
+}
+
+// DecryptSecret decrypts ciphertext with envName's declared secrets backend.
+func (m *manager) DecryptSecret(envName string, ciphertext []byte) ([]byte, error) {
+	enc, err := m.secretsEncryptor(envName)
+	if err != nil {
+		return nil, err
+	}
+	return enc.Decrypt(ciphertext)
+}
+
+// ReadSecretFile reads the raw (encrypted) contents of a file path relative
+// to envName's environment directory.
+func (m *manager) ReadSecretFile(envName, relPath string) ([]byte, error) {
+	envPath := appendToAbsPath(m.environmentsDir, envName)
+	path := appendToAbsPath(envPath, relPath)
+	return afero.ReadFile(m.appFS, string(path))
+}
+
+func (m *manager) secretsEncryptor(envName string) (secrets.Encryptor, error) {
+	cfg, err := m.secretsConfig(envName)
+	if err != nil {
+		return nil, err
+	}
+	return secrets.New(cfg)
+}
+
+func (m *manager) secretsConfig(envName string) (secrets.Config, error) {
+	envPath := appendToAbsPath(m.environmentsDir, envName)
+	path := appendToAbsPath(envPath, secretsFilename)
+
+	exists, err := afero.Exists(m.appFS, string(path))
+	if err != nil {
+		return secrets.Config{}, fmt.Errorf("environment %q: read secrets config: %v", envName, err)
+	}
+	if !exists {
+		// CreateEnvironment only writes secrets.yaml when a backend was
+		// explicitly configured; an environment without one defaults to
+		// the plaintext (no-op) backend.
+		return secrets.Config{}, nil
+	}
+
+	data, err := afero.ReadFile(m.appFS, string(path))
+	if err != nil {
+		return secrets.Config{}, fmt.Errorf("environment %q: read secrets config: %v", envName, err)
+	}
+
+	var cfg secrets.Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return secrets.Config{}, fmt.Errorf("environment %q: parse secrets config: %v", envName, err)
+	}
+
+	return cfg, nil
+}
+
+func (m *manager) writeSecretsConfig(envName string, cfg secrets.Config) error {
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+
+	envPath := appendToAbsPath(m.environmentsDir, envName)
+	path := appendToAbsPath(envPath, secretsFilename)
+	return afero.WriteFile(m.appFS, string(path), data, 0600)
+}
@@ -0,0 +1,65 @@
+// Copyright 2017 The kubecfg authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package metadata
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+
+	"github.com/ksonnet/kubecfg/metadata/secrets"
+)
+
+func TestSecretsConfigDefaultsToPlaintextWhenMissing(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	m := &manager{appFS: fs, environmentsDir: AbsPath("/app/environments")}
+
+	if err := fs.MkdirAll("/app/environments/default", 0755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+
+	cfg, err := m.secretsConfig("default")
+	if err != nil {
+		t.Fatalf("secretsConfig: unexpected error for environment with no secrets.yaml: %v", err)
+	}
+	if cfg.Backend != "" {
+		t.Errorf("secretsConfig = %+v, want zero Config (plaintext default)", cfg)
+	}
+
+	if _, err := secrets.New(cfg); err != nil {
+		t.Errorf("secrets.New(%+v): %v", cfg, err)
+	}
+}
+
+func TestSecretsConfigReadsExplicitBackend(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	m := &manager{appFS: fs, environmentsDir: AbsPath("/app/environments")}
+
+	if err := fs.MkdirAll("/app/environments/default", 0755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	if err := afero.WriteFile(fs, "/app/environments/default/secrets.yaml", []byte("backend: sops\nkeyRef: foo\n"), 0600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	cfg, err := m.secretsConfig("default")
+	if err != nil {
+		t.Fatalf("secretsConfig: %v", err)
+	}
+	if cfg.Backend != secrets.BackendSops || cfg.KeyRef != "foo" {
+		t.Errorf("secretsConfig = %+v, want {Backend: sops, KeyRef: foo}", cfg)
+	}
+}
@@ -0,0 +1,30 @@
+// Copyright 2017 The kubecfg authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package secrets
+
+// ageEncryptor shells out to the `age` binary. keyRef is a recipient public
+// key for Encrypt, and the path to an identity file for Decrypt.
+type ageEncryptor struct {
+	keyRef string
+}
+
+func (e *ageEncryptor) Encrypt(plaintext []byte) ([]byte, error) {
+	return run(plaintext, "age", "-r", e.keyRef)
+}
+
+func (e *ageEncryptor) Decrypt(ciphertext []byte) ([]byte, error) {
+	return run(ciphertext, "age", "-d", "-i", e.keyRef)
+}
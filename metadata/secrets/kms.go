@@ -0,0 +1,50 @@
+// Copyright 2017 The kubecfg authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package secrets
+
+// gcpKMSEncryptor shells out to `gcloud kms`, with keyRef as the full
+// resource name of the key: projects/.../locations/.../keyRings/.../cryptoKeys/...
+type gcpKMSEncryptor struct {
+	keyRef string
+}
+
+func (e *gcpKMSEncryptor) Encrypt(plaintext []byte) ([]byte, error) {
+	return run(plaintext, "gcloud", "kms", "encrypt",
+		"--key", e.keyRef, "--plaintext-file", "-", "--ciphertext-file", "-")
+}
+
+func (e *gcpKMSEncryptor) Decrypt(ciphertext []byte) ([]byte, error) {
+	return run(ciphertext, "gcloud", "kms", "decrypt",
+		"--key", e.keyRef, "--ciphertext-file", "-", "--plaintext-file", "-")
+}
+
+// awsKMSEncryptor shells out to `aws kms`, with keyRef as a key ID, alias,
+// or ARN.
+type awsKMSEncryptor struct {
+	keyRef string
+}
+
+func (e *awsKMSEncryptor) Encrypt(plaintext []byte) ([]byte, error) {
+	return run(plaintext, "aws", "kms", "encrypt",
+		"--key-id", e.keyRef, "--plaintext", "fileb://-",
+		"--output", "text", "--query", "CiphertextBlob")
+}
+
+func (e *awsKMSEncryptor) Decrypt(ciphertext []byte) ([]byte, error) {
+	return run(ciphertext, "aws", "kms", "decrypt",
+		"--ciphertext-blob", "fileb://-",
+		"--output", "text", "--query", "Plaintext")
+}
@@ -0,0 +1,62 @@
+// Copyright 2017 The kubecfg authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+// Package secrets provides pluggable encryption backends (sops, age,
+// gcp-kms, aws-kms, or plaintext) for an environment's secret values.
+package secrets
+
+import "fmt"
+
+// Backend names a supported encryption backend.
+type Backend string
+
+const (
+	BackendSops      Backend = "sops"
+	BackendAge       Backend = "age"
+	BackendGCPKMS    Backend = "gcp-kms"
+	BackendAWSKMS    Backend = "aws-kms"
+	BackendPlaintext Backend = "plaintext"
+)
+
+// Config is the encryption backend and key reference an environment
+// declares for its secrets, as persisted in environments/<name>/secrets.yaml.
+type Config struct {
+	Backend Backend `json:"backend"`
+	KeyRef  string  `json:"keyRef,omitempty"`
+}
+
+// Encryptor encrypts and decrypts secret payloads for a single backend.
+type Encryptor interface {
+	Encrypt(plaintext []byte) ([]byte, error)
+	Decrypt(ciphertext []byte) ([]byte, error)
+}
+
+// New constructs the Encryptor for cfg's backend.
+func New(cfg Config) (Encryptor, error) {
+	switch cfg.Backend {
+	case BackendSops:
+		return &sopsEncryptor{keyRef: cfg.KeyRef}, nil
+	case BackendAge:
+		return &ageEncryptor{keyRef: cfg.KeyRef}, nil
+	case BackendGCPKMS:
+		return &gcpKMSEncryptor{keyRef: cfg.KeyRef}, nil
+	case BackendAWSKMS:
+		return &awsKMSEncryptor{keyRef: cfg.KeyRef}, nil
+	case BackendPlaintext, "":
+		return plaintextEncryptor{}, nil
+	default:
+		return nil, fmt.Errorf("secrets: unsupported backend %q", cfg.Backend)
+	}
+}
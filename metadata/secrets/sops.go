@@ -0,0 +1,40 @@
+// Copyright 2017 The kubecfg authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package secrets
+
+// sopsEncryptor shells out to the `sops` binary, which already knows how to
+// read and write its own encrypted JSON envelope. keyRef, if set, is passed
+// through as the `--kms` key ARN; sops falls back to whatever key it finds
+// in its own config otherwise.
+type sopsEncryptor struct {
+	keyRef string
+}
+
+func (e *sopsEncryptor) Encrypt(plaintext []byte) ([]byte, error) {
+	return run(plaintext, "sops", e.args("--encrypt")...)
+}
+
+func (e *sopsEncryptor) Decrypt(ciphertext []byte) ([]byte, error) {
+	return run(ciphertext, "sops", e.args("--decrypt")...)
+}
+
+func (e *sopsEncryptor) args(mode string) []string {
+	args := []string{mode, "--input-type", "json", "--output-type", "json"}
+	if e.keyRef != "" {
+		args = append(args, "--kms", e.keyRef)
+	}
+	return append(args, "/dev/stdin")
+}
@@ -0,0 +1,137 @@
+// Copyright 2017 The kubecfg authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package metadata
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/afero"
+
+	"github.com/ksonnet/kubecfg/metadata/prototype"
+	"github.com/ksonnet/kubecfg/metadata/registry"
+)
+
+const prototypesDirname = "prototypes"
+
+// GetPrototype returns the prototype named id, searching every vendored
+// library under the vendor directory.
+func (m *manager) GetPrototype(id string) (*prototype.Prototype, error) {
+	protos, err := m.vendoredPrototypes()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, p := range protos {
+		if p.Name == id {
+			return p, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no such prototype %q", id)
+}
+
+// SearchPrototypes fuzzy-searches every vendored library's prototypes for
+// query, ranking matches by name, description, and tags.
+func (m *manager) SearchPrototypes(query string) ([]*prototype.Prototype, error) {
+	protos, err := m.vendoredPrototypes()
+	if err != nil {
+		return nil, err
+	}
+
+	return prototype.Index(protos).Search(query), nil
+}
+
+// VendorLibrary resolves pkgName@version against the named registry, fetches
+// and checksum-verifies its file tree, writes it under
+// vendor/<registryName>/<pkgName>/, and records the library in app.yaml.
+func (m *manager) VendorLibrary(registryName, pkgName, version string) error {
+	spec, err := m.registrySpec(registryName)
+	if err != nil {
+		return err
+	}
+
+	reg, err := registry.New(registryName, spec)
+	if err != nil {
+		return err
+	}
+
+	sha, err := reg.ResolveVersion(pkgName, version)
+	if err != nil {
+		return err
+	}
+
+	tree, err := reg.Fetch(pkgName, sha)
+	if err != nil {
+		return err
+	}
+
+	expected, err := reg.Checksum(pkgName, sha)
+	if err != nil {
+		return err
+	}
+	if actual := registry.Hash(tree); actual != expected {
+		return fmt.Errorf("vendor %s/%s: checksum mismatch: expected %s, got %s", registryName, pkgName, expected, actual)
+	}
+
+	pkgPath := appendToAbsPath(m.vendorDir, filepath.Join(registryName, pkgName))
+	for relPath, data := range tree {
+		dest := appendToAbsPath(pkgPath, relPath)
+		if err := m.appFS.MkdirAll(filepath.Dir(string(dest)), os.ModePerm); err != nil {
+			return err
+		}
+		if err := afero.WriteFile(m.appFS, string(dest), data, 0644); err != nil {
+			return err
+		}
+	}
+
+	return m.recordLibrary(pkgName, registryName, sha)
+}
+
+// vendoredPrototypes collects every prototype file found under a
+// `prototypes/` directory anywhere beneath the vendor directory.
+func (m *manager) vendoredPrototypes() ([]*prototype.Prototype, error) {
+	var protos []*prototype.Prototype
+
+	err := afero.Walk(m.appFS, string(m.vendorDir), func(path string, f os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if f.IsDir() || filepath.Ext(path) != ".jsonnet" {
+			return nil
+		}
+		if filepath.Base(filepath.Dir(path)) != prototypesDirname {
+			return nil
+		}
+
+		data, err := afero.ReadFile(m.appFS, path)
+		if err != nil {
+			return err
+		}
+
+		p, err := prototype.Parse(string(data))
+		if err != nil {
+			// Not every .jsonnet file under prototypes/ need be a well-formed
+			// prototype (e.g. a shared helper it imports); skip silently.
+			return nil
+		}
+		protos = append(protos, p)
+		return nil
+	})
+
+	return protos, err
+}
@@ -0,0 +1,37 @@
+// Copyright 2017 The kubecfg authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/ksonnet/kubecfg/metadata"
+	"github.com/ksonnet/kubecfg/pkg/apply"
+)
+
+// RunApply installs objects into the named environment. ordered is the
+// CLI's `--ordered` flag: when false, objects are applied in the order
+// given with no tiering, dependency-sorting, or readiness-blocking.
+func RunApply(root metadata.AbsPath, envName string, objects []runtime.Object, ordered bool, opts apply.ApplyOptions) error {
+	manager, err := metadata.Find(root)
+	if err != nil {
+		return err
+	}
+
+	opts.Unordered = !ordered
+
+	return manager.Apply(envName, objects, opts)
+}
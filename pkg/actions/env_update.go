@@ -0,0 +1,47 @@
+// Copyright 2017 The kubecfg authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"github.com/ksonnet/kubecfg/metadata"
+)
+
+// RunEnvUpdate updates the named environment's ksonnet-lib (and, if newURI is
+// non-empty, its server URI) against newSpec. It is the CLI-callable
+// counterpart of `Manager.UpdateEnvironment`/`UpdateEnvironmentURI`, invoked
+// by the `env update` command.
+func RunEnvUpdate(root metadata.AbsPath, name, newURI string, newSpec metadata.ClusterSpec) error {
+	manager, err := metadata.Find(root)
+	if err != nil {
+		return err
+	}
+
+	if err := genLib(manager, name, newSpec); err != nil {
+		return err
+	}
+
+	if newURI != "" {
+		return manager.UpdateEnvironmentURI(name, newURI)
+	}
+
+	return nil
+}
+
+// genLib regenerates ksonnet-lib for the named environment against spec,
+// rewriting only the files whose contents changed.
+func genLib(manager metadata.Manager, name string, spec metadata.ClusterSpec) error {
+	return manager.UpdateEnvironment(name, spec)
+}
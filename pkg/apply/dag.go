@@ -0,0 +1,123 @@
+// Copyright 2017 The kubecfg authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package apply
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// DependsOnAnnotation lets a manifest force ordering within its tier beyond
+// owner references, e.g. `kubecfg.io/depends-on: ConfigMap/my-config`.
+// Multiple dependencies are comma-separated.
+const DependsOnAnnotation = "kubecfg.io/depends-on"
+
+func key(obj *unstructured.Unstructured) string {
+	return obj.GetKind() + "/" + obj.GetName()
+}
+
+// sortTier Kahn-sorts a tier's objects by their owner references and
+// DependsOnAnnotation. If a cycle exists, it returns an error listing every
+// object that could not be sorted: the members of the cycle, plus any
+// objects that depend on them.
+func sortTier(objects []*unstructured.Unstructured) ([]*unstructured.Unstructured, error) {
+	byKey := make(map[string]*unstructured.Unstructured, len(objects))
+	for _, obj := range objects {
+		byKey[key(obj)] = obj
+	}
+
+	// edges[a] = [b, c] means a must be applied before b and c.
+	edges := make(map[string][]string, len(objects))
+	indegree := make(map[string]int, len(objects))
+	for _, obj := range objects {
+		indegree[key(obj)] = 0
+	}
+
+	for _, obj := range objects {
+		for _, dep := range dependencies(obj) {
+			if _, ok := byKey[dep]; !ok {
+				// Depends on something outside this tier (or not present at
+				// all); nothing for us to order here.
+				continue
+			}
+			k := key(obj)
+			edges[dep] = append(edges[dep], k)
+			indegree[k]++
+		}
+	}
+
+	var queue []string
+	for k, d := range indegree {
+		if d == 0 {
+			queue = append(queue, k)
+		}
+	}
+	sort.Strings(queue)
+
+	var ordered []string
+	for len(queue) > 0 {
+		k := queue[0]
+		queue = queue[1:]
+		ordered = append(ordered, k)
+
+		var unlocked []string
+		for _, dep := range edges[k] {
+			indegree[dep]--
+			if indegree[dep] == 0 {
+				unlocked = append(unlocked, dep)
+			}
+		}
+		sort.Strings(unlocked)
+		queue = append(queue, unlocked...)
+	}
+
+	if len(ordered) != len(objects) {
+		var stuck []string
+		for k, d := range indegree {
+			if d > 0 {
+				stuck = append(stuck, k)
+			}
+		}
+		sort.Strings(stuck)
+		return nil, fmt.Errorf("apply: dependency cycle among: %s", strings.Join(stuck, ", "))
+	}
+
+	result := make([]*unstructured.Unstructured, len(ordered))
+	for i, k := range ordered {
+		result[i] = byKey[k]
+	}
+	return result, nil
+}
+
+// dependencies returns the keys that obj must be installed after: its owner
+// references, plus anything named by its DependsOnAnnotation.
+func dependencies(obj *unstructured.Unstructured) []string {
+	var deps []string
+	for _, ref := range obj.GetOwnerReferences() {
+		deps = append(deps, ref.Kind+"/"+ref.Name)
+	}
+
+	if ann := obj.GetAnnotations()[DependsOnAnnotation]; ann != "" {
+		for _, dep := range strings.Split(ann, ",") {
+			deps = append(deps, strings.TrimSpace(dep))
+		}
+	}
+
+	return deps
+}
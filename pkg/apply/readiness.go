@@ -0,0 +1,110 @@
+// Copyright 2017 The kubecfg authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package apply
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	defaultTimeout = 5 * time.Minute
+	pollInterval   = 2 * time.Second
+)
+
+func timeoutFor(opts ApplyOptions) time.Duration {
+	if opts.Timeout > 0 {
+		return opts.Timeout
+	}
+	return defaultTimeout
+}
+
+// readinessChecks maps a kind to a typed check of whether an applied
+// instance has become ready. Kinds absent from this map are considered
+// ready as soon as they're applied.
+var readinessChecks = map[string]func(*unstructured.Unstructured) (bool, error){
+	"Deployment": func(obj *unstructured.Unstructured) (bool, error) {
+		replicas, found, _ := unstructured.NestedInt64(obj.Object, "spec", "replicas")
+		if !found {
+			// Kubernetes itself defaults an unset spec.replicas to 1.
+			replicas = 1
+		}
+		available, _, _ := unstructured.NestedInt64(obj.Object, "status", "availableReplicas")
+		return available >= replicas, nil
+	},
+	"CustomResourceDefinition": func(obj *unstructured.Unstructured) (bool, error) {
+		conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+		for _, c := range conditions {
+			cond, ok := c.(map[string]interface{})
+			if ok && cond["type"] == "Established" && cond["status"] == "True" {
+				return true, nil
+			}
+		}
+		return false, nil
+	},
+	"Job": func(obj *unstructured.Unstructured) (bool, error) {
+		succeeded, _, _ := unstructured.NestedInt64(obj.Object, "status", "succeeded")
+		return succeeded > 0, nil
+	},
+}
+
+// waitForReady blocks until every object in tier that has a readiness check
+// satisfies it, or timeout elapses.
+func waitForReady(client Client, tier []*unstructured.Unstructured, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+
+	pending := map[string]*unstructured.Unstructured{}
+	for _, obj := range tier {
+		if _, ok := readinessChecks[obj.GetKind()]; ok {
+			pending[key(obj)] = obj
+		}
+	}
+
+	for len(pending) > 0 {
+		if time.Now().After(deadline) {
+			var names []string
+			for k := range pending {
+				names = append(names, k)
+			}
+			sort.Strings(names)
+			return fmt.Errorf("apply: timed out waiting for ready: %v", names)
+		}
+
+		for k, obj := range pending {
+			current, err := client.Get(obj.GroupVersionKind(), obj.GetNamespace(), obj.GetName())
+			if err != nil {
+				return err
+			}
+
+			ready, err := readinessChecks[obj.GetKind()](current)
+			if err != nil {
+				return err
+			}
+			if ready {
+				delete(pending, k)
+			}
+		}
+
+		if len(pending) > 0 {
+			time.Sleep(pollInterval)
+		}
+	}
+
+	return nil
+}
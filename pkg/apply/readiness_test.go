@@ -0,0 +1,75 @@
+// Copyright 2017 The kubecfg authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package apply
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestDeploymentReadinessDefaultsUnsetReplicas(t *testing.T) {
+	cases := []struct {
+		name      string
+		spec      map[string]interface{}
+		status    map[string]interface{}
+		wantReady bool
+	}{
+		{
+			name:      "unset replicas, no available pods",
+			spec:      map[string]interface{}{},
+			status:    map[string]interface{}{},
+			wantReady: false,
+		},
+		{
+			name:      "unset replicas, one available pod",
+			spec:      map[string]interface{}{},
+			status:    map[string]interface{}{"availableReplicas": int64(1)},
+			wantReady: true,
+		},
+		{
+			name:      "explicit replicas satisfied",
+			spec:      map[string]interface{}{"replicas": int64(3)},
+			status:    map[string]interface{}{"availableReplicas": int64(3)},
+			wantReady: true,
+		},
+		{
+			name:      "explicit replicas not yet satisfied",
+			spec:      map[string]interface{}{"replicas": int64(3)},
+			status:    map[string]interface{}{"availableReplicas": int64(1)},
+			wantReady: false,
+		},
+	}
+
+	check := readinessChecks["Deployment"]
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			obj := &unstructured.Unstructured{Object: map[string]interface{}{
+				"spec":   c.spec,
+				"status": c.status,
+			}}
+
+			ready, err := check(obj)
+			if err != nil {
+				t.Fatalf("check: %v", err)
+			}
+			if ready != c.wantReady {
+				t.Errorf("check() = %v, want %v", ready, c.wantReady)
+			}
+		})
+	}
+}
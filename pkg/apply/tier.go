@@ -0,0 +1,60 @@
+// Copyright 2017 The kubecfg authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package apply
+
+import "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+// kindTiers lists the kinds that install in each ordering group, from first
+// to last. Kinds not listed here land in a final catch-all tier.
+var kindTiers = [][]string{
+	{"Namespace"},
+	{"CustomResourceDefinition"},
+	{"ServiceAccount", "Role", "RoleBinding", "ClusterRole", "ClusterRoleBinding"},
+	{"ConfigMap", "Secret"},
+	{"PersistentVolume", "PersistentVolumeClaim"},
+	{"Service"},
+	{"Deployment", "StatefulSet", "DaemonSet", "Job", "CronJob"},
+	{"Ingress", "NetworkPolicy"},
+}
+
+// tierize groups objects into kind tiers (in kindTiers order), plus one
+// final catch-all tier for any kinds kindTiers doesn't list. Empty tiers are
+// dropped.
+func tierize(objects []*unstructured.Unstructured) [][]*unstructured.Unstructured {
+	kindTier := map[string]int{}
+	for i, kinds := range kindTiers {
+		for _, k := range kinds {
+			kindTier[k] = i
+		}
+	}
+
+	groups := make([][]*unstructured.Unstructured, len(kindTiers)+1)
+	for _, obj := range objects {
+		i, ok := kindTier[obj.GetKind()]
+		if !ok {
+			i = len(kindTiers)
+		}
+		groups[i] = append(groups[i], obj)
+	}
+
+	var nonEmpty [][]*unstructured.Unstructured
+	for _, g := range groups {
+		if len(g) > 0 {
+			nonEmpty = append(nonEmpty, g)
+		}
+	}
+	return nonEmpty
+}
@@ -0,0 +1,69 @@
+// Copyright 2017 The kubecfg authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+// Package evaluate builds the jsonnet VM kubecfg evaluates an environment's
+// component and environment libsonnet files with.
+package evaluate
+
+import (
+	"encoding/json"
+	"fmt"
+
+	jsonnet "github.com/google/go-jsonnet"
+	"github.com/google/go-jsonnet/ast"
+
+	"github.com/ksonnet/kubecfg/metadata"
+)
+
+// NewVM constructs a jsonnet VM for evaluating envName's libsonnet files,
+// with kubecfg's native functions registered.
+func NewVM(manager metadata.Manager, envName string) *jsonnet.VM {
+	vm := jsonnet.MakeVM()
+	registerDecryptSecret(vm, manager, envName)
+	return vm
+}
+
+// registerDecryptSecret exposes `std.native("decryptSecret")(path)` to
+// jsonnet, so a `.libsonnet` file can reference an encrypted file (e.g.
+// "secrets/foo.enc.json", relative to the environment directory) and
+// receive its decrypted, JSON-decoded value at evaluation time.
+func registerDecryptSecret(vm *jsonnet.VM, manager metadata.Manager, envName string) {
+	vm.NativeFunction(&jsonnet.NativeFunction{
+		Name:   "decryptSecret",
+		Params: ast.Identifiers{"path"},
+		Func: func(args []interface{}) (interface{}, error) {
+			path, ok := args[0].(string)
+			if !ok {
+				return nil, fmt.Errorf("decryptSecret: path must be a string")
+			}
+
+			ciphertext, err := manager.ReadSecretFile(envName, path)
+			if err != nil {
+				return nil, err
+			}
+
+			plaintext, err := manager.DecryptSecret(envName, ciphertext)
+			if err != nil {
+				return nil, err
+			}
+
+			var value interface{}
+			if err := json.Unmarshal(plaintext, &value); err != nil {
+				return nil, err
+			}
+			return value, nil
+		},
+	})
+}
@@ -0,0 +1,74 @@
+// Copyright 2017 The kubecfg authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package evaluate
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ksonnet/kubecfg/metadata"
+)
+
+// fakeManager implements metadata.Manager by embedding a nil Manager and
+// overriding only the two methods registerDecryptSecret calls; any other
+// method would panic if exercised, which this test never does.
+type fakeManager struct {
+	metadata.Manager
+	secretFiles map[string][]byte
+}
+
+func (f *fakeManager) ReadSecretFile(envName, relPath string) ([]byte, error) {
+	data, ok := f.secretFiles[relPath]
+	if !ok {
+		return nil, fmt.Errorf("no such secret file %q", relPath)
+	}
+	return data, nil
+}
+
+// DecryptSecret stands in for a real backend's decryption; since this test
+// only exercises the native function's plumbing, it behaves like the
+// plaintext backend and returns ciphertext unchanged.
+func (f *fakeManager) DecryptSecret(envName string, ciphertext []byte) ([]byte, error) {
+	return ciphertext, nil
+}
+
+func TestDecryptSecretNativeFunction(t *testing.T) {
+	fm := &fakeManager{secretFiles: map[string][]byte{
+		"secrets/foo.enc.json": []byte(`{"password": "hunter2"}`),
+	}}
+
+	vm := NewVM(fm, "default")
+
+	out, err := vm.EvaluateSnippet("test.jsonnet", `std.native("decryptSecret")("secrets/foo.enc.json").password`)
+	if err != nil {
+		t.Fatalf("EvaluateSnippet: %v", err)
+	}
+
+	const want = "\"hunter2\"\n"
+	if out != want {
+		t.Errorf("EvaluateSnippet = %q, want %q", out, want)
+	}
+}
+
+func TestDecryptSecretNativeFunctionMissingFile(t *testing.T) {
+	fm := &fakeManager{secretFiles: map[string][]byte{}}
+
+	vm := NewVM(fm, "default")
+
+	if _, err := vm.EvaluateSnippet("test.jsonnet", `std.native("decryptSecret")("secrets/missing.enc.json")`); err == nil {
+		t.Fatal("EvaluateSnippet: got nil error for a missing secret file, want an error")
+	}
+}